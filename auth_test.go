@@ -0,0 +1,130 @@
+package gomjpeg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseDigestChallenge(t *testing.T) {
+	header := `Digest realm="cameras", qop="auth", nonce="abc123", opaque="xyz", algorithm=MD5`
+
+	challenge, ok := parseDigestChallenge(header)
+	if !ok {
+		t.Fatalf("expected a Digest challenge to parse")
+	}
+	if challenge.realm != "cameras" {
+		t.Errorf("realm = %q, want %q", challenge.realm, "cameras")
+	}
+	if challenge.qop != "auth" {
+		t.Errorf("qop = %q, want %q", challenge.qop, "auth")
+	}
+	if challenge.nonce != "abc123" {
+		t.Errorf("nonce = %q, want %q", challenge.nonce, "abc123")
+	}
+	if challenge.opaque != "xyz" {
+		t.Errorf("opaque = %q, want %q", challenge.opaque, "xyz")
+	}
+	if challenge.algorithm != "MD5" {
+		t.Errorf("algorithm = %q, want %q", challenge.algorithm, "MD5")
+	}
+}
+
+func TestParseDigestChallengeRejectsBasic(t *testing.T) {
+	_, ok := parseDigestChallenge(`Basic realm="cameras"`)
+	if ok {
+		t.Errorf("expected a Basic challenge to be rejected")
+	}
+}
+
+func TestDigestAuthHeaderWithQop(t *testing.T) {
+	challenge := digestChallenge{
+		realm: "cameras",
+		nonce: "abc123",
+		qop:   "auth",
+	}
+
+	header := digestAuthHeader(challenge, "GET", "/stream", "user", "pass")
+
+	ha1 := md5Hex("user:cameras:pass")
+	ha2 := md5Hex("GET:/stream")
+	if !strings.Contains(header, `username="user"`) {
+		t.Errorf("header missing username: %s", header)
+	}
+	if !strings.Contains(header, `nonce="abc123"`) {
+		t.Errorf("header missing nonce: %s", header)
+	}
+	if !strings.Contains(header, "qop=auth") {
+		t.Errorf("header missing qop: %s", header)
+	}
+	if !strings.Contains(header, `nc=00000001`) {
+		t.Errorf("header missing nc: %s", header)
+	}
+	// The response digest depends on a randomized cnonce and is not compared
+	// here; this only checks that md5Hex yields non-empty ha1/ha2 for the
+	// expected inputs.
+	if ha1 == "" || ha2 == "" {
+		t.Fatalf("expected non-empty ha1/ha2")
+	}
+}
+
+func TestSelectQopPrefersAuthFromList(t *testing.T) {
+	if got := selectQop("auth-int,auth"); got != "auth" {
+		t.Errorf("selectQop(%q) = %q, want %q", "auth-int,auth", got, "auth")
+	}
+	if got := selectQop("auth, auth-int"); got != "auth" {
+		t.Errorf("selectQop(%q) = %q, want %q", "auth, auth-int", got, "auth")
+	}
+}
+
+func TestSelectQopFallsBackToFirstOption(t *testing.T) {
+	if got := selectQop("auth-int"); got != "auth-int" {
+		t.Errorf("selectQop(%q) = %q, want %q", "auth-int", got, "auth-int")
+	}
+}
+
+func TestDigestAuthHeaderSelectsSingleQopFromList(t *testing.T) {
+	challenge := digestChallenge{
+		realm: "cameras",
+		nonce: "abc123",
+		qop:   "auth-int,auth",
+	}
+
+	header := digestAuthHeader(challenge, "GET", "/stream", "user", "pass")
+
+	if !strings.Contains(header, "qop=auth,") {
+		t.Errorf("header should echo back the single selected qop token, got: %s", header)
+	}
+	if strings.Contains(header, "qop=auth-int") {
+		t.Errorf("header should not echo the full qop-options list, got: %s", header)
+	}
+
+	cnonce := header[strings.Index(header, `cnonce="`)+len(`cnonce="`):]
+	cnonce = cnonce[:strings.IndexByte(cnonce, '"')]
+
+	ha1 := md5Hex("user:cameras:pass")
+	ha2 := md5Hex("GET:/stream")
+	wantResponse := md5Hex(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, challenge.nonce, "00000001", cnonce, "auth", ha2))
+	if !strings.Contains(header, `response="`+wantResponse+`"`) {
+		t.Errorf("response digest should be keyed off the selected qop %q, not the raw list %q", "auth", challenge.qop)
+	}
+}
+
+func TestDigestAuthHeaderWithoutQop(t *testing.T) {
+	challenge := digestChallenge{
+		realm: "cameras",
+		nonce: "abc123",
+	}
+
+	header := digestAuthHeader(challenge, "GET", "/stream", "user", "pass")
+
+	ha1 := md5Hex("user:cameras:pass")
+	ha2 := md5Hex("GET:/stream")
+	wantResponse := md5Hex(ha1 + ":" + challenge.nonce + ":" + ha2)
+	if !strings.Contains(header, `response="`+wantResponse+`"`) {
+		t.Errorf("header %s does not contain expected response %s", header, wantResponse)
+	}
+	if strings.Contains(header, "qop=") {
+		t.Errorf("header should omit qop when the challenge doesn't offer one: %s", header)
+	}
+}
@@ -0,0 +1,51 @@
+package gomjpeg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextBackoffDefaults(t *testing.T) {
+	m := &Mjpeg{}
+
+	got := m.nextBackoff(RetryPolicy{}, 0)
+	if got != time.Second {
+		t.Errorf("attempt 0 with zero-value policy: got %s, want %s", got, time.Second)
+	}
+
+	got = m.nextBackoff(RetryPolicy{}, 1)
+	if got != 2*time.Second {
+		t.Errorf("attempt 1 with zero-value policy: got %s, want %s", got, 2*time.Second)
+	}
+}
+
+func TestNextBackoffCapsAtMaxBackoff(t *testing.T) {
+	m := &Mjpeg{}
+	policy := RetryPolicy{
+		InitialBackoff: time.Second,
+		Multiplier:     2,
+		MaxBackoff:     5 * time.Second,
+	}
+
+	got := m.nextBackoff(policy, 10)
+	if got != 5*time.Second {
+		t.Errorf("attempt 10: got %s, want capped %s", got, 5*time.Second)
+	}
+}
+
+func TestNextBackoffJitterStaysInRange(t *testing.T) {
+	m := &Mjpeg{}
+	policy := RetryPolicy{
+		InitialBackoff: time.Second,
+		Multiplier:     2,
+		MaxBackoff:     10 * time.Second,
+		Jitter:         true,
+	}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		got := m.nextBackoff(policy, attempt)
+		if got < 0 || got > policy.MaxBackoff {
+			t.Errorf("attempt %d: jittered backoff %s out of [0, %s]", attempt, got, policy.MaxBackoff)
+		}
+	}
+}
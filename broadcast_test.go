@@ -0,0 +1,104 @@
+package gomjpeg
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+func newTestMjpeg() *Mjpeg {
+	return &Mjpeg{subscribers: make(map[int]*subscriber)}
+}
+
+func TestBroadcastImageDropNewest(t *testing.T) {
+	m := newTestMjpeg()
+	_, ch, _ := m.Subscribe(DropNewest, 1)
+
+	first := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	second := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	m.broadcastImage(first)
+	m.broadcastImage(second)
+
+	got := <-ch
+	if got != image.Image(first) {
+		t.Errorf("DropNewest should keep the queued frame and drop the new one")
+	}
+	select {
+	case <-ch:
+		t.Errorf("expected only one queued frame")
+	default:
+	}
+}
+
+func TestBroadcastImageDropOldest(t *testing.T) {
+	m := newTestMjpeg()
+	_, ch, _ := m.Subscribe(DropOldest, 1)
+
+	first := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	second := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	m.broadcastImage(first)
+	m.broadcastImage(second)
+
+	got := <-ch
+	if got != image.Image(second) {
+		t.Errorf("DropOldest should discard the queued frame in favor of the new one")
+	}
+}
+
+func TestBroadcastImageCoalesce(t *testing.T) {
+	m := newTestMjpeg()
+	_, ch, _ := m.Subscribe(Coalesce, 4)
+
+	frames := []image.Image{
+		image.NewRGBA(image.Rect(0, 0, 1, 1)),
+		image.NewRGBA(image.Rect(0, 0, 2, 2)),
+		image.NewRGBA(image.Rect(0, 0, 3, 3)),
+	}
+	for _, f := range frames {
+		m.broadcastImage(f)
+	}
+
+	got := <-ch
+	if got != frames[len(frames)-1] {
+		t.Errorf("Coalesce should keep only the latest frame")
+	}
+	select {
+	case <-ch:
+		t.Errorf("expected the buffer to be drained to a single frame")
+	default:
+	}
+}
+
+func TestBroadcastImageBlockDoesNotStallOtherSubscribers(t *testing.T) {
+	m := newTestMjpeg()
+	_, blocked, _ := m.Subscribe(Block, 1)
+	// Fill the Block subscriber's buffer so the next delivery to it blocks.
+	m.broadcastImage(image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	_, other, _ := m.Subscribe(DropNewest, 1)
+
+	frame := image.NewRGBA(image.Rect(0, 0, 1, 1))
+
+	done := make(chan struct{})
+	go func() {
+		m.broadcastImage(frame)
+		close(done)
+	}()
+
+	// The Block subscriber's own delivery blocks until drained, but the
+	// DropNewest subscriber must still have received its frame.
+	select {
+	case got := <-other:
+		if got != image.Image(frame) {
+			t.Errorf("other subscriber received wrong frame")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("broadcastImage stalled delivery to a non-blocked subscriber")
+	}
+
+	<-blocked
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("broadcastImage did not return after the blocked subscriber drained")
+	}
+}
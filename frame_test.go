@@ -0,0 +1,43 @@
+package gomjpeg
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestReadFrameByMarker(t *testing.T) {
+	m := &Mjpeg{}
+	data := []byte{0x00, 0x01, 0xFF, 0xD8, 0xAA, 0xBB, 0xCC, 0xFF, 0xD9, 0x99}
+	reader := bufio.NewReader(bytes.NewReader(data))
+
+	frame, err := m.readFrameByMarker(reader)
+	if err != nil {
+		t.Fatalf("readFrameByMarker returned error: %v", err)
+	}
+
+	want := []byte{0xFF, 0xD8, 0xAA, 0xBB, 0xCC, 0xFF, 0xD9}
+	if !bytes.Equal(frame, want) {
+		t.Errorf("frame = %v, want %v", frame, want)
+	}
+}
+
+func TestReadFrameByMarkerMissingEOI(t *testing.T) {
+	m := &Mjpeg{}
+	data := []byte{0xFF, 0xD8, 0xAA, 0xBB}
+	reader := bufio.NewReader(bytes.NewReader(data))
+
+	if _, err := m.readFrameByMarker(reader); err == nil {
+		t.Errorf("expected an error when the stream ends without an EOI marker")
+	}
+}
+
+func TestReadFrameByMarkerMissingSOI(t *testing.T) {
+	m := &Mjpeg{}
+	data := []byte{0x01, 0x02, 0x03}
+	reader := bufio.NewReader(bytes.NewReader(data))
+
+	if _, err := m.readFrameByMarker(reader); err == nil {
+		t.Errorf("expected an error when the stream ends without an SOI marker")
+	}
+}
@@ -0,0 +1,215 @@
+package gomjpeg
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"sync"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// ResampleKernel selects the interpolation kernel used when resizing frames.
+type ResampleKernel int
+
+const (
+	// ResampleApproxBiLinear is fast and adequate for most live-preview use cases.
+	ResampleApproxBiLinear ResampleKernel = iota
+	// ResampleCatmullRom trades speed for a sharper resize.
+	ResampleCatmullRom
+)
+
+func (k ResampleKernel) scaler() xdraw.Scaler {
+	if k == ResampleCatmullRom {
+		return xdraw.CatmullRom
+	}
+	return xdraw.ApproxBiLinear
+}
+
+// OutputFormat selects the concrete type processAndSendImage hands to
+// subscribers once decoding (and any resize) is done.
+type OutputFormat int
+
+const (
+	// FormatImage delivers the decoded/resized image.Image as-is.
+	FormatImage OutputFormat = iota
+	// FormatRGBA converts the result to *image.RGBA, useful for callers
+	// that need a concrete pixel format (e.g. GPU upload, X11 rendering).
+	FormatRGBA
+	// FormatJPEG re-encodes the result to JPEG, wrapping it in an
+	// EncodedImage so downstream re-broadcasters (see Server) can reuse
+	// the bytes instead of decoding and re-encoding again.
+	FormatJPEG
+)
+
+// EncodedImage is an image.Image that also retains the JPEG bytes it was
+// (re-)encoded to, letting a consumer that only needs raw bytes skip a
+// redundant decode/encode round-trip.
+type EncodedImage struct {
+	image.Image
+	JPEG []byte
+}
+
+// Resizer resizes src to the given dimensions.
+type Resizer interface {
+	Resize(src image.Image, w, h int) image.Image
+}
+
+// defaultResizer resizes with golang.org/x/image/draw using a configurable kernel.
+type defaultResizer struct {
+	kernel     xdraw.Scaler
+	keepAspect bool
+}
+
+func newDefaultResizer(kernel ResampleKernel, keepAspect bool) *defaultResizer {
+	return &defaultResizer{kernel: kernel.scaler(), keepAspect: keepAspect}
+}
+
+func (r *defaultResizer) Resize(src image.Image, w, h int) image.Image {
+	if w <= 0 || h <= 0 {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	destRect := dst.Bounds()
+	if r.keepAspect {
+		destRect = letterboxRect(src.Bounds(), w, h)
+	}
+
+	r.kernel.Scale(dst, destRect, src, src.Bounds(), xdraw.Over, nil)
+	return dst
+}
+
+// letterboxRect returns the sub-rectangle of a w x h canvas that src should
+// be scaled into to preserve its aspect ratio, centering it and leaving the
+// rest of the canvas blank.
+func letterboxRect(src image.Rectangle, w, h int) image.Rectangle {
+	srcW, srcH := src.Dx(), src.Dy()
+	if srcW == 0 || srcH == 0 {
+		return image.Rect(0, 0, w, h)
+	}
+
+	scale := math.Min(float64(w)/float64(srcW), float64(h)/float64(srcH))
+	scaledW := int(float64(srcW) * scale)
+	scaledH := int(float64(srcH) * scale)
+	offX := (w - scaledW) / 2
+	offY := (h - scaledH) / 2
+
+	return image.Rect(offX, offY, offX+scaledW, offY+scaledH)
+}
+
+// resizer returns the configured Resizer, building the default
+// draw-based one from ResampleKernel/KeepAspectRatio on first use.
+func (m *Mjpeg) resizer() Resizer {
+	m.resizerOnce.Do(func() {
+		if m.opts.Resizer != nil {
+			m.resizerInstance = m.opts.Resizer
+			return
+		}
+		m.resizerInstance = newDefaultResizer(m.opts.ResampleKernel, m.opts.KeepAspectRatio)
+	})
+	return m.resizerInstance
+}
+
+// applyOutputFormat converts img to the configured OutputFormat.
+func (m *Mjpeg) applyOutputFormat(img image.Image) image.Image {
+	switch m.opts.OutputFormat {
+	case FormatRGBA:
+		return toRGBA(img)
+
+	case FormatJPEG:
+		quality := m.opts.JPEGQuality
+		if quality <= 0 {
+			quality = 90
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			m.logf("Error re-encoding frame to JPEG: %v", err)
+			return img
+		}
+		return &EncodedImage{Image: img, JPEG: buf.Bytes()}
+
+	default: // FormatImage
+		return img
+	}
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba
+}
+
+// resizeJob carries the work a resizePool worker needs to finish a frame
+// and hand it to deliverImage.
+type resizeJob struct {
+	img          image.Image
+	timerStarted *bool
+}
+
+// resizePool runs resize (and output format conversion) work off the
+// reader goroutine so a slow resampler can't stall stream reads. Jobs that
+// back up beyond the pool's capacity are dropped rather than queued
+// unbounded, mirroring the drop-on-full behavior subscribers get from
+// their own DropPolicy.
+type resizePool struct {
+	jobs chan resizeJob
+}
+
+func newResizePool(m *Mjpeg, workers int) *resizePool {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	p := &resizePool{jobs: make(chan resizeJob, workers*2)}
+	for i := 0; i < workers; i++ {
+		go p.run(m)
+	}
+	return p
+}
+
+func (p *resizePool) run(m *Mjpeg) {
+	for job := range p.jobs {
+		img := m.resizer().Resize(job.img, m.opts.Width, m.opts.Height)
+		m.deliverImage(m.applyOutputFormat(img), job.timerStarted)
+	}
+}
+
+func (p *resizePool) submit(job resizeJob) {
+	select {
+	case p.jobs <- job:
+	default:
+		// Pool backed up; drop the frame rather than stalling the reader.
+	}
+}
+
+// stop shuts down the pool's workers. The caller must guarantee no
+// goroutine is still calling submit.
+func (p *resizePool) stop() {
+	close(p.jobs)
+}
+
+// ensureResizePool lazily starts the resize worker pool on first use.
+func (m *Mjpeg) ensureResizePool() {
+	m.resizePoolOnce.Do(func() {
+		m.resizePool = newResizePool(m, m.opts.ResizeWorkers)
+	})
+}
+
+// stopResizePool tears down the resize worker pool, if one was started, so
+// its goroutines don't leak for the rest of the process's life once the
+// stream stops. It resets the lazy-init guard so a later Start() rebuilds
+// the pool on demand. The caller must guarantee no goroutine is still
+// calling submit (i.e. decodeStream has already exited).
+func (m *Mjpeg) stopResizePool() {
+	if m.resizePool != nil {
+		m.resizePool.stop()
+		m.resizePool = nil
+	}
+	m.resizePoolOnce = sync.Once{}
+}
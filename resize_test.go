@@ -0,0 +1,36 @@
+package gomjpeg
+
+import (
+	"image"
+	"testing"
+)
+
+func TestLetterboxRectWiderCanvas(t *testing.T) {
+	// 100x100 source into a 200x100 canvas: scale is bound by height (1x),
+	// so the result should be centered horizontally with bars only at the
+	// sides and none above or below.
+	got := letterboxRect(image.Rect(0, 0, 100, 100), 200, 100)
+	want := image.Rect(50, 0, 150, 100)
+	if got != want {
+		t.Errorf("letterboxRect = %v, want %v", got, want)
+	}
+}
+
+func TestLetterboxRectTallerCanvas(t *testing.T) {
+	// 100x100 source into a 100x200 canvas: scale is bound by width (1x),
+	// so the result should be centered vertically with bars only above and
+	// below and none at the sides.
+	got := letterboxRect(image.Rect(0, 0, 100, 100), 100, 200)
+	want := image.Rect(0, 50, 100, 150)
+	if got != want {
+		t.Errorf("letterboxRect = %v, want %v", got, want)
+	}
+}
+
+func TestLetterboxRectZeroSizeSource(t *testing.T) {
+	got := letterboxRect(image.Rect(0, 0, 0, 0), 100, 50)
+	want := image.Rect(0, 0, 100, 50)
+	if got != want {
+		t.Errorf("letterboxRect with zero-size source = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,198 @@
+package gomjpeg
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBoundary is the multipart boundary used when re-broadcasting frames.
+	defaultBoundary = "gomjpegboundary"
+	// defaultBacklog is the default number of frames buffered per subscriber.
+	defaultBacklog = 5
+	// defaultJPEGQuality is used when re-encoding frames for re-broadcast.
+	defaultJPEGQuality = 90
+)
+
+// Server re-broadcasts the frames decoded by a Mjpeg client to any number of
+// HTTP clients as a multipart/x-mixed-replace stream. It implements
+// http.Handler so it can be mounted directly with net/http.
+type Server struct {
+	source *Mjpeg
+
+	// Boundary is the multipart boundary used in the Content-Type header
+	// and between parts. Defaults to defaultBoundary if empty.
+	Boundary string
+	// Backlog is the number of frames buffered per subscriber before the
+	// oldest frame is dropped to make room for new ones. Defaults to
+	// defaultBacklog if zero.
+	Backlog int
+	// FrameInterval, if non-zero, throttles how often frames are
+	// forwarded to subscribers, regardless of how fast the source produces them.
+	FrameInterval time.Duration
+
+	mu          sync.Mutex
+	subscribers map[chan []byte]bool
+
+	startOnce sync.Once
+}
+
+// NewServer creates a Server that re-broadcasts frames from source.
+// Call Start (or simply mount the Server as an http.Handler, which starts
+// it lazily on the first request) to begin pumping frames to subscribers.
+func NewServer(source *Mjpeg) *Server {
+	return &Server{
+		source:      source,
+		Boundary:    defaultBoundary,
+		Backlog:     defaultBacklog,
+		subscribers: make(map[chan []byte]bool),
+	}
+}
+
+// Start begins pumping decoded frames from the source into the server's
+// subscribers. It is safe to call multiple times; only the first call has
+// an effect.
+func (s *Server) Start() {
+	s.startOnce.Do(func() {
+		go s.pump()
+	})
+}
+
+func (s *Server) pump() {
+	// Subscribe directly rather than consuming s.source.ImageStream: that
+	// channel is replaced (and the old one closed) on every Mjpeg.Start(),
+	// so ranging over it directly would leave pump's loop permanently
+	// exited after the source is Stop()ped and Start()ed again. A direct
+	// subscription stays valid across the source's own start/stop cycles.
+	backlog := s.Backlog
+	if backlog <= 0 {
+		backlog = defaultBacklog
+	}
+	_, sub, _ := s.source.Subscribe(DropOldest, backlog)
+
+	var lastSent time.Time
+	for img := range sub {
+		if s.FrameInterval > 0 {
+			if elapsed := time.Since(lastSent); elapsed < s.FrameInterval {
+				continue
+			}
+			lastSent = time.Now()
+		}
+
+		frame, err := encodeFrame(img)
+		if err != nil {
+			s.source.logf("Error re-encoding frame for broadcast: %v", err)
+			continue
+		}
+		s.broadcast(frame)
+	}
+}
+
+// encodeFrame returns the JPEG bytes for img, reusing EncodedImage.JPEG
+// when the source already produced them (e.g. via MjpegOpts.OutputFormat
+// = FormatJPEG) instead of decoding and re-encoding again.
+func encodeFrame(img image.Image) ([]byte, error) {
+	if encoded, ok := img.(*EncodedImage); ok && encoded.JPEG != nil {
+		return encoded.JPEG, nil
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: defaultJPEGQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// broadcast fans frame out to every subscriber, dropping the oldest
+// buffered frame for any subscriber whose backlog is full.
+func (s *Server) broadcast(frame []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- frame:
+		default:
+			// Backlog full: drop the oldest queued frame and push the latest.
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- frame:
+			default:
+			}
+		}
+	}
+}
+
+func (s *Server) addSubscriber() chan []byte {
+	backlog := s.Backlog
+	if backlog <= 0 {
+		backlog = defaultBacklog
+	}
+	ch := make(chan []byte, backlog)
+
+	s.mu.Lock()
+	s.subscribers[ch] = true
+	s.mu.Unlock()
+
+	return ch
+}
+
+func (s *Server) removeSubscriber(ch chan []byte) {
+	s.mu.Lock()
+	delete(s.subscribers, ch)
+	s.mu.Unlock()
+}
+
+// ServeHTTP streams JPEG frames to w as they become available, using the
+// standard multipart/x-mixed-replace MJPEG framing.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Start()
+
+	boundary := s.Boundary
+	if boundary == "" {
+		boundary = defaultBoundary
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", fmt.Sprintf("multipart/x-mixed-replace; boundary=%s", boundary))
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusOK)
+
+	ch := s.addSubscriber()
+	defer s.removeSubscriber(ch)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case frame, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "\r\n--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n", boundary, len(frame)); err != nil {
+				return
+			}
+			if _, err := w.Write(frame); err != nil {
+				return
+			}
+			if _, err := w.Write([]byte("\r\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package gomjpeg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// Source abstracts where the multipart MJPEG byte stream comes from, so
+// the same parsing pipeline in connect/decodeStream can run against an
+// HTTP endpoint, a local capture device, or anything else that produces a
+// multipart/x-mixed-replace-shaped stream.
+type Source interface {
+	// Open begins producing the stream and returns its body together with
+	// the Content-Type header value used to discover the multipart
+	// boundary. The caller closes the returned ReadCloser once the stream
+	// ends or ctx is cancelled.
+	Open(ctx context.Context) (body io.ReadCloser, contentType string, err error)
+}
+
+// HTTPSource is the default Source: it fetches the stream over HTTP(S) as
+// configured by the owning Mjpeg's MjpegOpts (URL, Headers, auth, TLS, etc.).
+type HTTPSource struct {
+	m *Mjpeg
+}
+
+// Open implements Source.
+func (s *HTTPSource) Open(ctx context.Context) (io.ReadCloser, string, error) {
+	res, err := s.m.getStreamResponse(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return res.Body, res.Header.Get("Content-Type"), nil
+}
+
+// ffmpegBoundary is the multipart boundary ffmpeg's mpjpeg muxer emits.
+const ffmpegBoundary = "ffmpeg"
+
+// FFmpegSource captures frames from a local video device (e.g. Linux
+// V4L2's /dev/video0) by spawning ffmpeg and piping its mpjpeg-muxed
+// stdout into the existing multipart parser.
+type FFmpegSource struct {
+	// Device is the input device passed to ffmpeg's -i flag.
+	Device string
+	// FPS is the capture frame rate (-r). Left to ffmpeg's default if zero.
+	FPS int
+	// Quality is the JPEG quality passed to -q:v (2-31, lower is better).
+	// Left to ffmpeg's default if zero.
+	Quality int
+	// Width and Height set the capture resolution (-s WxH). Left to
+	// ffmpeg's default for the device if either is zero.
+	Width, Height int
+	// FFmpegPath overrides the ffmpeg binary to run. Defaults to
+	// "ffmpeg" resolved via PATH if empty.
+	FFmpegPath string
+}
+
+// NewV4L2Source builds an FFmpegSource that captures from a Linux V4L2
+// device such as /dev/video0.
+func NewV4L2Source(device string, fps, quality, w, h int) *FFmpegSource {
+	return &FFmpegSource{
+		Device:  device,
+		FPS:     fps,
+		Quality: quality,
+		Width:   w,
+		Height:  h,
+	}
+}
+
+// Open implements Source by spawning ffmpeg and returning its stdout.
+func (s *FFmpegSource) Open(ctx context.Context) (io.ReadCloser, string, error) {
+	ffmpegPath := s.FFmpegPath
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+
+	args := []string{"-f", "v4l2", "-i", s.Device, "-f", "mpjpeg"}
+	if s.Quality > 0 {
+		args = append(args, "-q:v", fmt.Sprintf("%d", s.Quality))
+	}
+	if s.FPS > 0 {
+		args = append(args, "-r", fmt.Sprintf("%d", s.FPS))
+	}
+	if s.Width > 0 && s.Height > 0 {
+		args = append(args, "-s", fmt.Sprintf("%dx%d", s.Width, s.Height))
+	}
+	args = append(args, "pipe:1")
+
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating ffmpeg stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, "", fmt.Errorf("error starting ffmpeg: %w", err)
+	}
+	go func() { _ = cmd.Wait() }()
+
+	return stdout, fmt.Sprintf("multipart/x-mixed-replace; boundary=%s", ffmpegBoundary), nil
+}